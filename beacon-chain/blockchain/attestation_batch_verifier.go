@@ -0,0 +1,316 @@
+package blockchain
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls"
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+)
+
+// ErrBatchVerifierFull is returned by Enqueue when the verifier's bounded
+// input channel is saturated and the caller should apply backpressure.
+var ErrBatchVerifierFull = errors.New("attestation batch verifier: queue is full")
+
+// Attestation is the minimal surface of a gossip attestation the batch
+// verifier needs: its data (for grouping and labeling) and its raw BLS
+// signature.
+type Attestation interface {
+	GetData() *ethpb.AttestationData
+	GetSignature() []byte
+}
+
+// AttestationPool is the minimal interface the batch verifier needs in
+// order to hand verified attestations directly to the pool, without an
+// intermediate queue.
+type AttestationPool interface {
+	SaveAttestation(att Attestation) error
+}
+
+// pendingAttestation is a single gossip attestation waiting to be batch
+// verified, along with the material required to do so.
+type pendingAttestation struct {
+	att         Attestation
+	pubKey      bls.PublicKey
+	signingRoot [32]byte
+	attester    primitives.ValidatorIndex
+}
+
+// batchKey groups pending attestations so that a single batched verification
+// call only ever spans attestations that share a committee and a target
+// checkpoint root prefix. The root prefix is a cheap proxy for "same
+// checkpoint/epoch" grouping; it is not a fork version or signing domain.
+type batchKey struct {
+	targetRootPrefix [4]byte
+	committeeIndex   primitives.CommitteeIndex
+}
+
+// orderedGroups accumulates pending attestations into batchKey groups while
+// remembering the order in which each group was first seen, so batches can
+// later be flushed in that same order rather than in Go's randomized map
+// iteration order.
+type orderedGroups struct {
+	order  []batchKey
+	groups map[batchKey][]*pendingAttestation
+}
+
+func newOrderedGroups() *orderedGroups {
+	return &orderedGroups{groups: make(map[batchKey][]*pendingAttestation)}
+}
+
+// add appends p to key's group, recording key's first-seen order if this is
+// a new group, and returns the group's new size.
+func (g *orderedGroups) add(key batchKey, p *pendingAttestation) int {
+	if _, ok := g.groups[key]; !ok {
+		g.order = append(g.order, key)
+	}
+	g.groups[key] = append(g.groups[key], p)
+	return len(g.groups[key])
+}
+
+// flushAllInOrder calls verify once per group, in the order each group was
+// first seen, and then clears every group.
+func (g *orderedGroups) flushAllInOrder(verify func([]*pendingAttestation)) {
+	for _, key := range g.order {
+		verify(g.groups[key])
+	}
+	g.order = nil
+	g.groups = make(map[batchKey][]*pendingAttestation)
+}
+
+// flushThroughInOrder flushes every group at or before key's position in
+// first-seen order (including key's own group), then removes them, leaving
+// any more-recently-seen groups pending. This is what lets a size-triggered
+// flush of one group happen without ever verifying it ahead of an older
+// group that is still accumulating: every older group is forced to flush
+// alongside it, in order.
+func (g *orderedGroups) flushThroughInOrder(key batchKey, verify func([]*pendingAttestation)) {
+	idx := -1
+	for i, k := range g.order {
+		if k == key {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		return
+	}
+	for i := 0; i <= idx; i++ {
+		verify(g.groups[g.order[i]])
+		delete(g.groups, g.order[i])
+	}
+	g.order = g.order[idx+1:]
+}
+
+// BatchVerifierConfig controls how aggressively the AttestationBatchVerifier
+// coalesces incoming attestations before issuing a batch verification call.
+type BatchVerifierConfig struct {
+	// MaxBatchSize is the maximum number of attestations accumulated for a
+	// single batch key before the batch is flushed early.
+	MaxBatchSize int
+	// MaxBatchWindow is the maximum amount of time an attestation will sit
+	// in the queue before its batch is flushed, regardless of size.
+	MaxBatchWindow time.Duration
+	// QueueSize bounds the number of attestations that may be pending
+	// verification at once, providing backpressure to gossip handling.
+	QueueSize int
+}
+
+// DefaultBatchVerifierConfig returns sane batching defaults for mainnet-sized
+// committees.
+func DefaultBatchVerifierConfig() *BatchVerifierConfig {
+	return &BatchVerifierConfig{
+		MaxBatchSize:   128,
+		MaxBatchWindow: 50 * time.Millisecond,
+		QueueSize:      4096,
+	}
+}
+
+// AttestationBatchVerifier accumulates incoming gossip attestations for a
+// short window, groups them by target checkpoint root and committee, and
+// issues a single batched BLS verification call per group instead of
+// verifying each attestation individually. Verified attestations are
+// handed directly to the attestation pool. If a batch fails verification,
+// the verifier falls back to verifying each attestation in that batch
+// individually so that only the genuinely invalid signatures are counted as
+// failures.
+//
+// A single background goroutine drains the input queue and always flushes
+// groups in the order they were first seen, which keeps batches ordered
+// relative to one another and satisfies the ordering guarantees the fork
+// choice store relies on when attestations are later applied.
+type AttestationBatchVerifier struct {
+	cfg   *BatchVerifierConfig
+	stats *AttestationVerificationStats
+	pool  AttestationPool
+
+	queue chan *pendingAttestation
+
+	flushMu sync.Mutex
+}
+
+// NewAttestationBatchVerifier creates a verifier that reports into stats and
+// forwards successfully verified attestations to pool. A nil cfg falls back
+// to DefaultBatchVerifierConfig.
+func NewAttestationBatchVerifier(cfg *BatchVerifierConfig, stats *AttestationVerificationStats, pool AttestationPool) *AttestationBatchVerifier {
+	if cfg == nil {
+		cfg = DefaultBatchVerifierConfig()
+	}
+	return &AttestationBatchVerifier{
+		cfg:   cfg,
+		stats: stats,
+		pool:  pool,
+		queue: make(chan *pendingAttestation, cfg.QueueSize),
+	}
+}
+
+// Enqueue submits an attestation for batched verification. attester is the
+// validator index attesting (the caller already knows this from gossip
+// topic/committee assignment) and is used solely to attribute a failure if
+// this attestation is later rejected. Enqueue returns ErrBatchVerifierFull
+// if the bounded queue is saturated, or ctx.Err() if the context is
+// canceled first.
+func (v *AttestationBatchVerifier) Enqueue(ctx context.Context, att Attestation, pubKey bls.PublicKey, signingRoot [32]byte, attester primitives.ValidatorIndex) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	p := &pendingAttestation{att: att, pubKey: pubKey, signingRoot: signingRoot, attester: attester}
+	select {
+	case v.queue <- p:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		return ErrBatchVerifierFull
+	}
+}
+
+// Start launches the background batching loop in a new goroutine. The
+// goroutine exits once ctx is canceled, after flushing any remaining
+// attestations. Calling Flush() after canceling ctx drains whatever is still
+// in the queue, but it does not wait for the goroutine to exit: groups the
+// goroutine has already taken off the queue may still be being verified
+// when Flush() returns.
+func (v *AttestationBatchVerifier) Start(ctx context.Context) {
+	go v.run(ctx)
+}
+
+// Flush blocks until every attestation currently queued has been verified
+// and handed to the pool (or counted as a failure), in the order their
+// groups were first seen. It is intended for use during shutdown.
+func (v *AttestationBatchVerifier) Flush() {
+	v.flushMu.Lock()
+	defer v.flushMu.Unlock()
+
+	groups := newOrderedGroups()
+	for {
+		select {
+		case p := <-v.queue:
+			groups.add(batchKeyFor(p), p)
+		default:
+			groups.flushAllInOrder(v.verifyBatch)
+			return
+		}
+	}
+}
+
+// run is the background loop that coalesces attestations into batches and
+// verifies each batch once it reaches cfg.MaxBatchSize or cfg.MaxBatchWindow
+// elapses, whichever happens first. Groups are always flushed in the order
+// they were first seen, which is what lets a single draining goroutine
+// preserve the relative ordering the fork choice store depends on.
+func (v *AttestationBatchVerifier) run(ctx context.Context) {
+	groups := newOrderedGroups()
+	timer := time.NewTimer(v.cfg.MaxBatchWindow)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			groups.flushAllInOrder(v.verifyBatch)
+			v.Flush()
+			return
+		case p := <-v.queue:
+			key := batchKeyFor(p)
+			if groups.add(key, p) >= v.cfg.MaxBatchSize {
+				// Flushing only this group would let it jump ahead of an
+				// older, still-accumulating group, breaking the ordering
+				// guarantee the fork choice store relies on. Flush every
+				// group at or before this one in first-seen order instead.
+				groups.flushThroughInOrder(key, v.verifyBatch)
+			}
+		case <-timer.C:
+			groups.flushAllInOrder(v.verifyBatch)
+			timer.Reset(v.cfg.MaxBatchWindow)
+		}
+	}
+}
+
+func batchKeyFor(p *pendingAttestation) batchKey {
+	var prefix [4]byte
+	copy(prefix[:], p.att.GetData().Target.Root[:4])
+	return batchKey{targetRootPrefix: prefix, committeeIndex: p.att.GetData().CommitteeIndex}
+}
+
+// verifyBatch issues a single bls.VerifyMultipleSignatures call covering
+// every attestation in group, each signature checked against its own
+// signing root. On success the whole batch is counted and forwarded
+// to the pool in order. On failure it falls back to verifying each
+// attestation individually so that only the truly-bad signatures are
+// counted into failedReasons.
+func (v *AttestationBatchVerifier) verifyBatch(group []*pendingAttestation) {
+	if len(group) == 0 {
+		return
+	}
+
+	sigs := make([][]byte, len(group))
+	msgs := make([][32]byte, len(group))
+	pubKeys := make([]bls.PublicKey, len(group))
+	for i, p := range group {
+		sigs[i] = p.att.GetSignature()
+		msgs[i] = p.signingRoot
+		pubKeys[i] = p.pubKey
+	}
+
+	start := time.Now()
+	ok, err := bls.VerifyMultipleSignatures(sigs, msgs, pubKeys)
+	batchDur := time.Since(start)
+	if err == nil && ok {
+		v.stats.ObserveDuration("success", batchDur)
+		// A batch only shares committee and target root prefix, not slot, so
+		// label each attestation's success with its own slot rather than
+		// crediting the whole batch to the first attestation's slot bucket.
+		for _, p := range group {
+			data := p.att.GetData()
+			v.stats.IncrementSuccess(data.Slot, data.CommitteeIndex)
+			if err := v.pool.SaveAttestation(p.att); err != nil {
+				log.WithError(err).Error("Could not save batch-verified attestation to pool")
+			}
+		}
+		return
+	}
+	v.stats.ObserveDuration(FailureReasonBLSBatchFallbackInvalidSignature.String(), batchDur)
+
+	// The batch failed as a whole; fall back to per-message verification so
+	// only the genuinely invalid signatures are counted as failures.
+	for _, p := range group {
+		data := p.att.GetData()
+		attemptStart := time.Now()
+		sig, sigErr := bls.SignatureFromBytes(p.att.GetSignature())
+		valid := sigErr == nil && sig.Verify(p.pubKey, p.signingRoot[:])
+		attemptDur := time.Since(attemptStart)
+		if !valid {
+			v.stats.IncrementFailureFor(FailureReasonBLSBatchFallbackInvalidSignature, data.CommitteeIndex, p.attester)
+			v.stats.ObserveDuration(FailureReasonBLSBatchFallbackInvalidSignature.String(), attemptDur)
+			continue
+		}
+		v.stats.IncrementSuccess(data.Slot, data.CommitteeIndex)
+		v.stats.ObserveDuration("success", attemptDur)
+		if err := v.pool.SaveAttestation(p.att); err != nil {
+			log.WithError(err).Error("Could not save fallback-verified attestation to pool")
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package blockchain
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls"
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+)
+
+// fakeAttestation is a minimal Attestation for tests; it carries only the
+// fields the batch verifier actually reads.
+type fakeAttestation struct {
+	data *ethpb.AttestationData
+	sig  []byte
+}
+
+func (f *fakeAttestation) GetData() *ethpb.AttestationData { return f.data }
+func (f *fakeAttestation) GetSignature() []byte            { return f.sig }
+
+// fakePool records every attestation handed to it.
+type fakePool struct {
+	mu    sync.Mutex
+	saved []Attestation
+}
+
+func (p *fakePool) SaveAttestation(att Attestation) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.saved = append(p.saved, att)
+	return nil
+}
+
+func (p *fakePool) savedCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.saved)
+}
+
+// newSignedTestAttestation builds an attestation signed by a fresh random
+// key for slot/committee, all sharing targetRoot so they land in the same
+// batch. If valid is false, the signature is corrupted so it fails
+// verification.
+func newSignedTestAttestation(t *testing.T, slot primitives.Slot, committee primitives.CommitteeIndex, targetRoot [32]byte, valid bool) (*pendingAttestation, primitives.ValidatorIndex) {
+	t.Helper()
+
+	sk, err := bls.RandKey()
+	if err != nil {
+		t.Fatalf("bls.RandKey() failed: %v", err)
+	}
+	sig := sk.Sign(targetRoot[:]).Marshal()
+	if !valid {
+		sig = append([]byte{}, sig...)
+		sig[0] ^= 0xff
+	}
+
+	data := &ethpb.AttestationData{
+		Slot:            slot,
+		CommitteeIndex:  committee,
+		BeaconBlockRoot: make([]byte, 32),
+		Source:          &ethpb.Checkpoint{Root: make([]byte, 32)},
+		Target:          &ethpb.Checkpoint{Root: targetRoot[:]},
+	}
+
+	attester := primitives.ValidatorIndex(slot)*1000 + primitives.ValidatorIndex(committee)
+	return &pendingAttestation{
+		att:         &fakeAttestation{data: data, sig: sig},
+		pubKey:      sk.PublicKey(),
+		signingRoot: targetRoot,
+		attester:    attester,
+	}, attester
+}
+
+func newTestVerifier(cfg *BatchVerifierConfig) (*AttestationBatchVerifier, *AttestationVerificationStats, *fakePool) {
+	stats := NewAttestationVerificationStats()
+	pool := &fakePool{}
+	return NewAttestationBatchVerifier(cfg, stats, pool), stats, pool
+}
+
+func TestAttestationBatchVerifier_SizeTriggeredFlush(t *testing.T) {
+	v, _, pool := newTestVerifier(&BatchVerifierConfig{
+		MaxBatchSize:   3,
+		MaxBatchWindow: time.Hour, // large enough that only the size trigger can fire
+		QueueSize:      10,
+	})
+
+	var targetRoot [32]byte
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	v.Start(ctx)
+
+	for i := 0; i < 3; i++ {
+		p, _ := newSignedTestAttestation(t, primitives.Slot(i), 0, targetRoot, true)
+		if err := v.Enqueue(ctx, p.att, p.pubKey, p.signingRoot, p.attester); err != nil {
+			t.Fatalf("Enqueue() failed: %v", err)
+		}
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for pool.savedCount() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := pool.savedCount(); got != 3 {
+		t.Errorf("pool.savedCount() = %d, want 3 (size-triggered flush did not run)", got)
+	}
+}
+
+func TestAttestationBatchVerifier_TimerTriggeredFlush(t *testing.T) {
+	v, _, pool := newTestVerifier(&BatchVerifierConfig{
+		MaxBatchSize:   1000, // large enough that only the timer can fire
+		MaxBatchWindow: 10 * time.Millisecond,
+		QueueSize:      10,
+	})
+
+	var targetRoot [32]byte
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	v.Start(ctx)
+
+	p, _ := newSignedTestAttestation(t, 0, 0, targetRoot, true)
+	if err := v.Enqueue(ctx, p.att, p.pubKey, p.signingRoot, p.attester); err != nil {
+		t.Fatalf("Enqueue() failed: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for pool.savedCount() < 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := pool.savedCount(); got != 1 {
+		t.Errorf("pool.savedCount() = %d, want 1 (timer-triggered flush did not run)", got)
+	}
+}
+
+func TestAttestationBatchVerifier_FallbackAccounting(t *testing.T) {
+	v, stats, pool := newTestVerifier(&BatchVerifierConfig{
+		MaxBatchSize:   2,
+		MaxBatchWindow: time.Hour,
+		QueueSize:      10,
+	})
+
+	var targetRoot [32]byte
+	good, _ := newSignedTestAttestation(t, 0, 0, targetRoot, true)
+	bad, badAttester := newSignedTestAttestation(t, 1, 0, targetRoot, false)
+
+	v.Flush() // no-op, queue empty
+	ctx := context.Background()
+	if err := v.Enqueue(ctx, good.att, good.pubKey, good.signingRoot, good.attester); err != nil {
+		t.Fatalf("Enqueue() failed: %v", err)
+	}
+	if err := v.Enqueue(ctx, bad.att, bad.pubKey, bad.signingRoot, bad.attester); err != nil {
+		t.Fatalf("Enqueue() failed: %v", err)
+	}
+	v.Flush()
+
+	if got := pool.savedCount(); got != 1 {
+		t.Errorf("pool.savedCount() = %d, want 1 (only the valid attestation should be saved)", got)
+	}
+
+	stats.Lock()
+	failures := stats.failedReasons[FailureReasonBLSBatchFallbackInvalidSignature]
+	offenders := stats.topOffenders[FailureReasonBLSBatchFallbackInvalidSignature]
+	stats.Unlock()
+	if failures != 1 {
+		t.Errorf("failedReasons[FailureReasonBLSBatchFallbackInvalidSignature] = %d, want 1", failures)
+	}
+	if len(offenders) != 1 || offenders[0].Attester != badAttester {
+		t.Errorf("unexpected top offenders: %+v, want attester %d", offenders, badAttester)
+	}
+}
+
+func TestAttestationBatchVerifier_Enqueue_Backpressure(t *testing.T) {
+	v, _, _ := newTestVerifier(&BatchVerifierConfig{
+		MaxBatchSize:   10,
+		MaxBatchWindow: time.Hour,
+		QueueSize:      1,
+	})
+
+	var targetRoot [32]byte
+	ctx := context.Background()
+	p1, _ := newSignedTestAttestation(t, 0, 0, targetRoot, true)
+	p2, _ := newSignedTestAttestation(t, 1, 0, targetRoot, true)
+
+	if err := v.Enqueue(ctx, p1.att, p1.pubKey, p1.signingRoot, p1.attester); err != nil {
+		t.Fatalf("first Enqueue() failed: %v", err)
+	}
+	if err := v.Enqueue(ctx, p2.att, p2.pubKey, p2.signingRoot, p2.attester); err != ErrBatchVerifierFull {
+		t.Errorf("second Enqueue() = %v, want ErrBatchVerifierFull", err)
+	}
+}
+
+func TestAttestationBatchVerifier_Flush_DrainsOnShutdown(t *testing.T) {
+	v, _, pool := newTestVerifier(&BatchVerifierConfig{
+		MaxBatchSize:   100,
+		MaxBatchWindow: time.Hour,
+		QueueSize:      10,
+	})
+
+	var targetRoot [32]byte
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		p, _ := newSignedTestAttestation(t, primitives.Slot(i), 0, targetRoot, true)
+		if err := v.Enqueue(ctx, p.att, p.pubKey, p.signingRoot, p.attester); err != nil {
+			t.Fatalf("Enqueue() failed: %v", err)
+		}
+	}
+
+	// Nothing should have been verified yet: no background loop is running
+	// and neither the size nor time threshold has been reached.
+	if got := pool.savedCount(); got != 0 {
+		t.Fatalf("pool.savedCount() = %d, want 0 before Flush()", got)
+	}
+
+	v.Flush()
+	if got := pool.savedCount(); got != 5 {
+		t.Errorf("pool.savedCount() = %d, want 5 after Flush()", got)
+	}
+}
@@ -0,0 +1,70 @@
+package blockchain
+
+// AttestationFailureReason enumerates the concrete reasons the blockchain
+// package can reject an attestation during verification. Using a bounded
+// enum instead of a free-form string keeps failedReasons, and the metric and
+// log labels derived from it, bounded regardless of what a validator-
+// controlled value (attester index, block root, etc.) a caller might
+// otherwise have been tempted to pass in.
+type AttestationFailureReason uint16
+
+const (
+	// FailureReasonUnknown is the zero value and should not be passed to
+	// IncrementFailure directly; it exists so a zero AttestationFailureReason
+	// is recognizable as "not set" rather than aliasing a real reason.
+	FailureReasonUnknown AttestationFailureReason = iota
+	// FailureReasonInvalidSignature indicates the attestation's BLS
+	// signature did not verify against its expected signer(s).
+	FailureReasonInvalidSignature
+	// FailureReasonUnknownTargetRoot indicates the attestation's target
+	// checkpoint root does not correspond to a block known to the node.
+	FailureReasonUnknownTargetRoot
+	// FailureReasonInvalidCommitteeIndex indicates the attestation's
+	// committee index is out of range for its slot's committee count.
+	FailureReasonInvalidCommitteeIndex
+	// FailureReasonFutureSlot indicates the attestation's slot is further
+	// ahead of the current slot than gossip validation allows.
+	FailureReasonFutureSlot
+	// FailureReasonPastSlot indicates the attestation's slot is further
+	// behind the current slot than gossip validation allows.
+	FailureReasonPastSlot
+	// FailureReasonDuplicate indicates an attestation with the same
+	// attesting indices and data has already been verified this epoch.
+	FailureReasonDuplicate
+	// FailureReasonInvalidAggregationBits indicates the attestation's
+	// aggregation bitfield is malformed (wrong length, no bits set, or more
+	// than one bit set for an unaggregated attestation).
+	FailureReasonInvalidAggregationBits
+	// FailureReasonBLSBatchFallbackInvalidSignature indicates an
+	// attestation failed individual verification during the per-message
+	// fallback that follows a failed batch verification call.
+	FailureReasonBLSBatchFallbackInvalidSignature
+
+	// numAttestationFailureReasons is a sentinel marking the number of
+	// defined reasons above; it is not itself a valid reason and exists so
+	// tests can iterate every defined value.
+	numAttestationFailureReasons
+)
+
+// attestationFailureReasonNames gives each AttestationFailureReason a
+// stable, bounded string used for log fields and metric labels.
+var attestationFailureReasonNames = map[AttestationFailureReason]string{
+	FailureReasonUnknown:                          "unknown",
+	FailureReasonInvalidSignature:                 "invalid_signature",
+	FailureReasonUnknownTargetRoot:                "unknown_target_root",
+	FailureReasonInvalidCommitteeIndex:            "invalid_committee_index",
+	FailureReasonFutureSlot:                       "future_slot",
+	FailureReasonPastSlot:                         "past_slot",
+	FailureReasonDuplicate:                        "duplicate",
+	FailureReasonInvalidAggregationBits:           "invalid_aggregation_bits",
+	FailureReasonBLSBatchFallbackInvalidSignature: "bls_batch_fallback_invalid_sig",
+}
+
+// String returns the stable name for r, or "unrecognized_failure_reason" if
+// r is not one of the defined constants above.
+func (r AttestationFailureReason) String() string {
+	if name, ok := attestationFailureReasonNames[r]; ok {
+		return name
+	}
+	return "unrecognized_failure_reason"
+}
@@ -0,0 +1,20 @@
+package blockchain
+
+import "testing"
+
+// TestAttestationFailureReason_Exhaustive ensures attestationFailureReasonNames
+// has an entry for every AttestationFailureReason constant. Adding a new
+// reason without adding its name here causes this test to fail, so the
+// String() output (and the metric/log labels derived from it) can never
+// silently fall back to "unrecognized_failure_reason" for a reason the
+// package itself defines.
+func TestAttestationFailureReason_Exhaustive(t *testing.T) {
+	for r := AttestationFailureReason(0); r < numAttestationFailureReasons; r++ {
+		if _, ok := attestationFailureReasonNames[r]; !ok {
+			t.Errorf("AttestationFailureReason %d has no entry in attestationFailureReasonNames", r)
+		}
+	}
+	if len(attestationFailureReasonNames) != int(numAttestationFailureReasons) {
+		t.Errorf("attestationFailureReasonNames has %d entries, want %d", len(attestationFailureReasonNames), numAttestationFailureReasons)
+	}
+}
@@ -0,0 +1,200 @@
+package blockchain
+
+import (
+	"container/heap"
+	"encoding/binary"
+
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+)
+
+const (
+	// cmsWidth and cmsDepth size the count-min sketch used to approximate
+	// per-validator failure counts. At width=2048, depth=4 the sketch stays
+	// a fixed, small amount of memory (4*2048 uint64 counters per reason)
+	// regardless of how many distinct validators misbehave in an epoch.
+	cmsWidth = 2048
+	cmsDepth = 4
+
+	// topOffendersPerReason bounds how many offending validators are kept
+	// per failure reason.
+	topOffendersPerReason = 16
+)
+
+// cmsSeeds are independent murmur3 seeds for each row of the count-min
+// sketch, chosen so the rows hash validator indices independently of one
+// another.
+var cmsSeeds = [cmsDepth]uint32{0x9e3779b9, 0x85ebca6b, 0xc2b2ae35, 0x27d4eb2f}
+
+// countMinSketch is a fixed-size, probabilistic frequency counter. It never
+// grows regardless of the number of distinct keys observed, trading a small
+// amount of overcounting (never undercounting) for O(1) memory. This is used
+// to approximate per-validator failure counts without keeping an unbounded
+// map keyed by validator index, which would let a validator-controlled
+// value drive unbounded memory growth.
+type countMinSketch struct {
+	rows [cmsDepth][cmsWidth]uint64
+}
+
+// add records one occurrence of key and returns the sketch's current
+// estimate of key's total count (the minimum across rows, which is the
+// standard count-min estimator).
+func (c *countMinSketch) add(key uint64) uint64 {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], key)
+
+	estimate := uint64(0)
+	for row, seed := range cmsSeeds {
+		idx := murmur3Sum32(buf[:], seed) % cmsWidth
+		c.rows[row][idx]++
+		if row == 0 || c.rows[row][idx] < estimate {
+			estimate = c.rows[row][idx]
+		}
+	}
+	return estimate
+}
+
+// murmur3Sum32 is a standard 32-bit murmur3 implementation. It is used only
+// to spread keys across count-min sketch buckets and is not
+// security-sensitive.
+func murmur3Sum32(data []byte, seed uint32) uint32 {
+	const (
+		c1 = 0xcc9e2d51
+		c2 = 0x1b873593
+	)
+	h := seed
+	nBlocks := len(data) / 4
+	for i := 0; i < nBlocks; i++ {
+		k := binary.LittleEndian.Uint32(data[i*4:])
+		k *= c1
+		k = (k << 15) | (k >> 17)
+		k *= c2
+		h ^= k
+		h = (h << 13) | (h >> 19)
+		h = h*5 + 0xe6546b64
+	}
+
+	tail := data[nBlocks*4:]
+	var k uint32
+	switch len(tail) {
+	case 3:
+		k ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		k ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		k ^= uint32(tail[0])
+		k *= c1
+		k = (k << 15) | (k >> 17)
+		k *= c2
+		h ^= k
+	}
+
+	h ^= uint32(len(data))
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
+
+// OffenderCount is one entry in a top-K offender breakdown: a validator
+// index and its approximate number of failed verifications for a given
+// reason in the current epoch.
+type OffenderCount struct {
+	Attester          primitives.ValidatorIndex
+	EstimatedFailures uint64
+}
+
+// offenderHeap is a min-heap of OffenderCount ordered by EstimatedFailures,
+// used to keep only the top topOffendersPerReason offenders per reason in
+// O(log topOffendersPerReason) time.
+type offenderHeap []OffenderCount
+
+func (h offenderHeap) Len() int            { return len(h) }
+func (h offenderHeap) Less(i, j int) bool  { return h[i].EstimatedFailures < h[j].EstimatedFailures }
+func (h offenderHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *offenderHeap) Push(x interface{}) { *h = append(*h, x.(OffenderCount)) }
+func (h *offenderHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// recordOffender updates the count-min sketch and top-K heap for reason
+// with one additional observed failure from attester. Memory use per reason
+// is O(cmsWidth*cmsDepth + topOffendersPerReason), independent of how many
+// distinct validators are ever observed.
+func (a *AttestationVerificationStats) recordOffender(reason AttestationFailureReason, attester primitives.ValidatorIndex) {
+	sketch, ok := a.offenderSketches[reason]
+	if !ok {
+		sketch = &countMinSketch{}
+		a.offenderSketches[reason] = sketch
+	}
+	estimate := sketch.add(uint64(attester))
+
+	h := a.topOffenders[reason]
+	for i, entry := range h {
+		if entry.Attester == attester {
+			h[i].EstimatedFailures = estimate
+			heap.Fix(&h, i)
+			a.topOffenders[reason] = h
+			return
+		}
+	}
+
+	entry := OffenderCount{Attester: attester, EstimatedFailures: estimate}
+	if len(h) < topOffendersPerReason {
+		heap.Push(&h, entry)
+	} else if len(h) > 0 && estimate > h[0].EstimatedFailures {
+		heap.Pop(&h)
+		heap.Push(&h, entry)
+	}
+	a.topOffenders[reason] = h
+}
+
+// topOffendersSnapshot returns a copy of the current top offenders for
+// reason, ordered from the most to least failures.
+func topOffendersSnapshot(h offenderHeap) []OffenderCount {
+	out := make([]OffenderCount, len(h))
+	copy(out, h)
+	// heap order only guarantees the root is the minimum; sort descending
+	// for a human- and operator-friendly breakdown.
+	for i := 1; i < len(out); i++ {
+		for j := i; j > 0 && out[j-1].EstimatedFailures < out[j].EstimatedFailures; j-- {
+			out[j-1], out[j] = out[j], out[j-1]
+		}
+	}
+	return out
+}
+
+// CommitteeOffenderCount is one entry in a top-K committee breakdown: a
+// committee index and its exact number of failed verifications for a given
+// reason in the current epoch.
+type CommitteeOffenderCount struct {
+	Committee primitives.CommitteeIndex
+	Failures  uint64
+}
+
+// topCommitteeOffenders converts counts into a slice sorted from most to
+// least failures, capped at topOffendersPerReason entries. Committee counts
+// are already bounded (there are only a handful of committees per slot), so
+// this is a reporting convenience rather than a memory-bounding mechanism.
+func topCommitteeOffenders(counts map[primitives.CommitteeIndex]uint64) []CommitteeOffenderCount {
+	out := make([]CommitteeOffenderCount, 0, len(counts))
+	for committee, failures := range counts {
+		out = append(out, CommitteeOffenderCount{Committee: committee, Failures: failures})
+	}
+	for i := 1; i < len(out); i++ {
+		for j := i; j > 0 && out[j-1].Failures < out[j].Failures; j-- {
+			out[j-1], out[j] = out[j], out[j-1]
+		}
+	}
+	if len(out) > topOffendersPerReason {
+		out = out[:topOffendersPerReason]
+	}
+	return out
+}
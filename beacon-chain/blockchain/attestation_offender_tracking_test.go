@@ -0,0 +1,46 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+)
+
+func TestAttestationVerificationStats_IncrementFailureFor_BoundsTopOffenders(t *testing.T) {
+	stats := NewAttestationVerificationStats()
+
+	// Simulate far more distinct offenders than topOffendersPerReason to
+	// make sure the heap never grows past its bound.
+	for i := 0; i < topOffendersPerReason*4; i++ {
+		stats.IncrementFailureFor(FailureReasonInvalidSignature, primitives.CommitteeIndex(i%64), primitives.ValidatorIndex(i))
+	}
+
+	stats.Lock()
+	got := len(stats.topOffenders[FailureReasonInvalidSignature])
+	stats.Unlock()
+
+	if got > topOffendersPerReason {
+		t.Errorf("top offenders heap has %d entries, want at most %d", got, topOffendersPerReason)
+	}
+}
+
+func TestAttestationVerificationStats_SnapshotAndReset_ClearsOffenders(t *testing.T) {
+	stats := NewAttestationVerificationStats()
+	stats.IncrementFailureFor(FailureReasonDuplicate, 0, 7)
+
+	_, failures, committeeOffenders, topOffenders := stats.SnapshotAndReset()
+	if failures[FailureReasonDuplicate] != 1 {
+		t.Errorf("failures[FailureReasonDuplicate] = %d, want 1", failures[FailureReasonDuplicate])
+	}
+	if len(topOffenders[FailureReasonDuplicate]) != 1 || topOffenders[FailureReasonDuplicate][0].Attester != 7 {
+		t.Errorf("unexpected top offenders snapshot: %+v", topOffenders[FailureReasonDuplicate])
+	}
+	if len(committeeOffenders[FailureReasonDuplicate]) != 1 || committeeOffenders[FailureReasonDuplicate][0].Committee != 0 {
+		t.Errorf("unexpected committee offenders snapshot: %+v", committeeOffenders[FailureReasonDuplicate])
+	}
+
+	_, failuresAfterReset, _, _ := stats.SnapshotAndReset()
+	if len(failuresAfterReset) != 0 {
+		t.Errorf("expected failures to be reset, got %+v", failuresAfterReset)
+	}
+}
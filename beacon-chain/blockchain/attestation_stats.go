@@ -11,56 +11,101 @@ import (
 // It uses a Mutex to ensure concurrency safety for all accesses.
 type AttestationVerificationStats struct {
 	sync.Mutex
-	successfulCount uint64
-	failedReasons   map[string]uint64
+	successfulCount   uint64
+	failedReasons     map[AttestationFailureReason]uint64
+	committeeFailures map[AttestationFailureReason]map[primitives.CommitteeIndex]uint64
+	offenderSketches  map[AttestationFailureReason]*countMinSketch
+	topOffenders      map[AttestationFailureReason]offenderHeap
 }
 
 // NewAttestationVerificationStats creates a new stats struct with a fresh map of failed reasons.
 func NewAttestationVerificationStats() *AttestationVerificationStats {
 	return &AttestationVerificationStats{
-		failedReasons: make(map[string]uint64),
+		failedReasons:     make(map[AttestationFailureReason]uint64),
+		committeeFailures: make(map[AttestationFailureReason]map[primitives.CommitteeIndex]uint64),
+		offenderSketches:  make(map[AttestationFailureReason]*countMinSketch),
+		topOffenders:      make(map[AttestationFailureReason]offenderHeap),
 	}
 }
 
-// IncrementSuccess increments the count of successfully verified attestations.
-func (a *AttestationVerificationStats) IncrementSuccess() {
+// IncrementSuccess increments the count of successfully verified
+// attestations. slot and committeeIndex identify the attestation that was
+// verified and are used to label the Prometheus counters.
+func (a *AttestationVerificationStats) IncrementSuccess(slot primitives.Slot, committeeIndex primitives.CommitteeIndex) {
 	a.Lock()
-	defer a.Unlock()
 	a.successfulCount++
+	a.Unlock()
+	successCounter.WithLabelValues(slotBucketLabel(slot), committeeIndexLabel(committeeIndex)).Inc()
 }
 
 // IncrementFailure increments the count for a specific failure reason.
-func (a *AttestationVerificationStats) IncrementFailure(reason string) {
+func (a *AttestationVerificationStats) IncrementFailure(reason AttestationFailureReason) {
+	a.Lock()
+	a.failedReasons[reason]++
+	a.Unlock()
+	failureCounter.WithLabelValues(reason.String()).Inc()
+}
+
+// IncrementFailureFor increments the count for reason, the same as
+// IncrementFailure, and additionally attributes the failure to committee
+// and attester. Per-committee counts are kept exactly, since the number of
+// committees per slot is small and bounded. Per-validator attribution is
+// kept approximately via a count-min sketch and a bounded top-K heap, so
+// memory use stays O(1) per reason even if an attacker controls which
+// validator index is reported.
+func (a *AttestationVerificationStats) IncrementFailureFor(reason AttestationFailureReason, committee primitives.CommitteeIndex, attester primitives.ValidatorIndex) {
 	a.Lock()
-	defer a.Unlock()
 	a.failedReasons[reason]++
+	committees, ok := a.committeeFailures[reason]
+	if !ok {
+		committees = make(map[primitives.CommitteeIndex]uint64)
+		a.committeeFailures[reason] = committees
+	}
+	committees[committee]++
+	a.recordOffender(reason, attester)
+	a.Unlock()
+	failureCounter.WithLabelValues(reason.String()).Inc()
 }
 
-// SnapshotAndReset returns the current stats and then resets them.
-// This ensures we have a clean slate for the next epoch.
-func (a *AttestationVerificationStats) SnapshotAndReset() (uint64, map[string]uint64) {
+// SnapshotAndReset returns the current stats, including the top-K offending
+// committees and validators per failure reason, and then resets them. This
+// ensures we have a clean slate for the next epoch.
+func (a *AttestationVerificationStats) SnapshotAndReset() (uint64, map[AttestationFailureReason]uint64, map[AttestationFailureReason][]CommitteeOffenderCount, map[AttestationFailureReason][]OffenderCount) {
 	a.Lock()
 	defer a.Unlock()
 
 	// Take a snapshot of current counts
 	successes := a.successfulCount
-	failures := make(map[string]uint64, len(a.failedReasons))
+	failures := make(map[AttestationFailureReason]uint64, len(a.failedReasons))
 	for k, v := range a.failedReasons {
 		failures[k] = v
 	}
 
+	committeeOffenders := make(map[AttestationFailureReason][]CommitteeOffenderCount, len(a.committeeFailures))
+	for reason, counts := range a.committeeFailures {
+		committeeOffenders[reason] = topCommitteeOffenders(counts)
+	}
+
+	topOffenders := make(map[AttestationFailureReason][]OffenderCount, len(a.topOffenders))
+	for reason, h := range a.topOffenders {
+		topOffenders[reason] = topOffendersSnapshot(h)
+	}
+
 	// Reset counts
 	a.successfulCount = 0
-	a.failedReasons = make(map[string]uint64)
+	a.failedReasons = make(map[AttestationFailureReason]uint64)
+	a.committeeFailures = make(map[AttestationFailureReason]map[primitives.CommitteeIndex]uint64)
+	a.offenderSketches = make(map[AttestationFailureReason]*countMinSketch)
+	a.topOffenders = make(map[AttestationFailureReason]offenderHeap)
 
-	return successes, failures
+	return successes, failures, committeeOffenders, topOffenders
 }
 
 // LogEpochSummaryAndReset logs the current epoch's attestation verification summary
 // (success/failure counts) and then resets the stats for the next epoch.
 func (a *AttestationVerificationStats) LogEpochSummaryAndReset(epoch primitives.Epoch) {
 	// Snapshot current stats and reset them to avoid mixing data from multiple epochs.
-	successes, failures := a.SnapshotAndReset()
+	successes, failures, committeeOffenders, topOffenders := a.SnapshotAndReset()
 
 	// Prepare log fields: current epoch and number of successful verifications.
 	fields := logrus.Fields{
@@ -68,9 +113,27 @@ func (a *AttestationVerificationStats) LogEpochSummaryAndReset(epoch primitives.
 		"successful_verifications": successes,
 	}
 
-	// Add each failure reason and its count to the log fields.
+	// Add each failure reason and its count to the log fields, keyed by the
+	// reason's stable name so the set of possible keys stays bounded.
 	for reason, count := range failures {
-		fields["fail_"+reason] = count
+		fields["fail_"+reason.String()] = count
+	}
+
+	// Add a compact top-offending-committees breakdown per reason.
+	for reason, offenders := range committeeOffenders {
+		if len(offenders) == 0 {
+			continue
+		}
+		fields["top_committees_"+reason.String()] = offenders
+	}
+
+	// Add a compact top-offenders breakdown per reason so operators can spot
+	// equivocating or misbehaving validators without scraping raw counters.
+	for reason, offenders := range topOffenders {
+		if len(offenders) == 0 {
+			continue
+		}
+		fields["top_offenders_"+reason.String()] = offenders
 	}
 
 	// Log the summarized data for this epoch, helping operators track trends and issues.
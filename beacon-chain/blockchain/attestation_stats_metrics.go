@@ -0,0 +1,64 @@
+package blockchain
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+)
+
+// registerAttestationStatsMetricsOnce ensures the collectors below are only
+// registered with the default Prometheus registerer a single time, even if
+// multiple AttestationVerificationStats instances are created.
+var registerAttestationStatsMetricsOnce sync.Once
+
+var (
+	successCounter *prometheus.CounterVec
+	failureCounter *prometheus.CounterVec
+	latencyHist    *prometheus.HistogramVec
+)
+
+func init() {
+	registerAttestationStatsMetricsOnce.Do(func() {
+		successCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "attestation_verification_success_total",
+			Help: "Cumulative count of successfully verified attestations.",
+		}, []string{"slot_bucket", "committee_index"})
+
+		failureCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "attestation_verification_failure_total",
+			Help: "Cumulative count of attestations that failed verification, by reason.",
+		}, []string{"reason"})
+
+		latencyHist = promauto.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "attestation_verification_latency_seconds",
+			Help:    "Latency of attestation verification, by outcome reason.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"reason"})
+	})
+}
+
+// ObserveDuration records how long a verification attempt took, labeled by
+// reason (e.g. "success" or one of the failure reason keys). The counters
+// above are cumulative and are never reset by SnapshotAndReset, so Grafana
+// rate() queries remain accurate across epoch boundaries.
+func (a *AttestationVerificationStats) ObserveDuration(reason string, d time.Duration) {
+	latencyHist.WithLabelValues(reason).Observe(d.Seconds())
+}
+
+// slotBucketLabel buckets a slot by its position within its epoch (0 to
+// SlotsPerEpoch-1). Unlike the epoch number, which grows monotonically for
+// the life of the chain, a slot's position within its epoch is intrinsically
+// bounded, so this label can never accumulate new values over time.
+func slotBucketLabel(slot primitives.Slot) string {
+	return strconv.FormatUint(uint64(slot%params.BeaconConfig().SlotsPerEpoch), 10)
+}
+
+// committeeIndexLabel renders a committee index as a metric label.
+func committeeIndexLabel(committeeIndex primitives.CommitteeIndex) string {
+	return strconv.FormatUint(uint64(committeeIndex), 10)
+}